@@ -0,0 +1,52 @@
+package customerimporter
+
+import (
+	"context"
+	"io"
+)
+
+// ctxReader wraps an io.Reader so a Read blocked inside the underlying
+// reader is abandoned as soon as ctx is done, rather than leaving the
+// caller parked inside it forever. This matters because encoding/csv.Reader
+// (and the BOM peek in decodeReader) call Read synchronously with no
+// cancellation of their own; without this wrapper, ctx cancellation or
+// Importer.Stop only take effect between reads, not during one blocked on a
+// slow or stalled source (a network socket, an io.Pipe with nothing
+// written to it).
+//
+// Read runs the underlying Read in its own goroutine and copies its result
+// back on success. If ctx is done first, Read returns ctx.Err() immediately
+// and the goroutine is abandoned; since the underlying reader may never
+// unblock on its own, this goroutine can leak for the lifetime of that
+// reader, which is an accepted tradeoff for making an arbitrary io.Reader
+// cancellable.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+type ctxReadResult struct {
+	n   int
+	err error
+}
+
+func (c *ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	resultCh := make(chan ctxReadResult, 1)
+	buf := make([]byte, len(p))
+	go func() {
+		n, err := c.r.Read(buf)
+		resultCh <- ctxReadResult{n: n, err: err}
+	}()
+
+	select {
+	case res := <-resultCh:
+		copy(p, buf[:res.n])
+		return res.n, res.err
+	case <-c.ctx.Done():
+		return 0, c.ctx.Err()
+	}
+}
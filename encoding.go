@@ -0,0 +1,55 @@
+package customerimporter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// decodeReader strips a leading UTF-8 BOM, if present, and wraps r in a
+// golang.org/x/text/encoding transform.Reader when opts.Encoding names a
+// non-UTF-8 charset, so CSVs exported by non-English systems decode cleanly
+// before they ever reach encoding/csv.
+func decodeReader(r io.Reader, opts Options) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	if bom, err := br.Peek(len(utf8BOM)); err == nil && bytes.Equal(bom, utf8BOM) {
+		br.Discard(len(utf8BOM))
+	}
+
+	enc, err := encodingByName(opts.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	if enc == nil {
+		return br, nil
+	}
+
+	return transform.NewReader(br, enc.NewDecoder()), nil
+}
+
+// encodingByName resolves an Options.Encoding value to a golang.org/x/text
+// encoding.Encoding. It returns a nil Encoding (and nil error) for UTF-8,
+// since that needs no transform.Reader wrapping.
+func encodingByName(name string) (encoding.Encoding, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "utf-8", "utf8":
+		return nil, nil
+	case "gbk":
+		return simplifiedchinese.GBK, nil
+	case "latin1", "iso-8859-1", "iso8859-1":
+		return charmap.ISO8859_1, nil
+	case "windows-1252", "windows1252", "cp1252":
+		return charmap.Windows1252, nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q", name)
+	}
+}
@@ -0,0 +1,76 @@
+package customerimporter
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestStreamDomainCountsStripsBOM(t *testing.T) {
+	csvData := "first_name,last_name,email,gender,ip_address\n" +
+		"John,Doe,thatGuyDoe@faceSmile.net,Male,53.191.87.821\n"
+
+	var buf bytes.Buffer
+	buf.Write(utf8BOM)
+	buf.WriteString(csvData)
+
+	result, _, err := streamDomainCounts(context.Background(), &buf, Options{}, nil)
+	if err != nil {
+		t.Fatalf("streamDomainCounts returned an error: %v", err)
+	}
+
+	expected := map[string]int{"facesmile.net": 1}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("streamDomainCounts returned %v, expected %v", result, expected)
+	}
+}
+
+func TestStreamDomainCountsLatin1(t *testing.T) {
+	csvData := "first_name,last_name,email,gender,ip_address\n" +
+		"Ren\xe9,Doe,rene@example.com,Male,1.2.3.4\n"
+
+	result, _, err := streamDomainCounts(context.Background(), strings.NewReader(csvData), Options{Encoding: "latin1"}, nil)
+	if err != nil {
+		t.Fatalf("streamDomainCounts returned an error: %v", err)
+	}
+
+	expected := map[string]int{"example.com": 1}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("streamDomainCounts returned %v, expected %v", result, expected)
+	}
+}
+
+func TestStreamDomainCountsSemicolonComma(t *testing.T) {
+	csvData := "first_name;last_name;email;gender;ip_address\n" +
+		"John;Doe;thatGuyDoe@faceSmile.net;Male;53.191.87.821\n"
+
+	result, _, err := streamDomainCounts(context.Background(), strings.NewReader(csvData), Options{Comma: ';'}, nil)
+	if err != nil {
+		t.Fatalf("streamDomainCounts returned an error: %v", err)
+	}
+
+	expected := map[string]int{"facesmile.net": 1}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("streamDomainCounts returned %v, expected %v", result, expected)
+	}
+}
+
+func TestEncodingByNameUnsupported(t *testing.T) {
+	if _, err := encodingByName("shift-jis"); err == nil {
+		t.Error("encodingByName(\"shift-jis\") returned nil error, expected one")
+	}
+}
+
+func TestEncodingByNameKnown(t *testing.T) {
+	enc, err := encodingByName("windows-1252")
+	if err != nil {
+		t.Fatalf("encodingByName returned an error: %v", err)
+	}
+	if enc != charmap.Windows1252 {
+		t.Errorf("encodingByName(\"windows-1252\") = %v, expected charmap.Windows1252", enc)
+	}
+}
@@ -0,0 +1,177 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// State is the lifecycle state of an Importer.
+type State int
+
+const (
+	Idle State = iota
+	Importing
+	Stopping
+	Finished
+	Failed
+)
+
+func (s State) String() string {
+	switch s {
+	case Idle:
+		return "idle"
+	case Importing:
+		return "importing"
+	case Stopping:
+		return "stopping"
+	case Finished:
+		return "finished"
+	case Failed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// Status is a point-in-time snapshot of an Importer. Total is the number of
+// records read so far (Processed + Rejected); for a streaming, single-pass
+// import it isn't known in advance, so it only reaches its final value once
+// the import finishes.
+type Status struct {
+	Total     int
+	Processed int
+	Rejected  int
+	StartedAt time.Time
+	State     State
+}
+
+// NotifyCB is invoked every time an Importer's State changes, so a caller
+// (a CLI progress bar, an HTTP handler) can react to progress without
+// polling Status in a loop.
+type NotifyCB func(Status)
+
+// Importer drives a single streaming import and exposes its live Status,
+// modeled on the listmonk subimporter's stateful Importer type: it can be
+// started from a long-running service, stopped early from another
+// goroutine, and polled for Status concurrently while Run is in progress.
+// An Importer is for a single Run; create a new one for the next import.
+type Importer struct {
+	opts   Options
+	notify NotifyCB
+
+	processed atomic.Int64
+	rejected  atomic.Int64
+
+	mu        sync.RWMutex
+	state     State
+	startedAt time.Time
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// NewImporter creates an Importer in the Idle state. notify may be nil if
+// the caller only wants to poll Status.
+func NewImporter(opts Options, notify NotifyCB) *Importer {
+	return &Importer{opts: opts, notify: notify, stopCh: make(chan struct{})}
+}
+
+// Status returns a snapshot of the importer's current state.
+func (im *Importer) Status() Status {
+	im.mu.RLock()
+	defer im.mu.RUnlock()
+
+	return im.statusLocked()
+}
+
+// statusLocked builds a Status snapshot from the current fields. Callers
+// must hold im.mu (for reading or writing).
+func (im *Importer) statusLocked() Status {
+	processed := int(im.processed.Load())
+	rejected := int(im.rejected.Load())
+	return Status{
+		Total:     processed + rejected,
+		Processed: processed,
+		Rejected:  rejected,
+		StartedAt: im.startedAt,
+		State:     im.state,
+	}
+}
+
+// Stop requests cancellation of an in-progress Run. It is safe to call from
+// any goroutine, any number of times, including before Run has started
+// (in which case the next Run returns immediately).
+func (im *Importer) Stop() {
+	im.mu.Lock()
+	if im.state == Importing {
+		im.state = Stopping
+	}
+	im.mu.Unlock()
+
+	im.stopOnce.Do(func() { close(im.stopCh) })
+}
+
+// Run executes the import, reading CSV data from r and honoring both ctx
+// cancellation and an explicit Stop call. It blocks until the import
+// finishes, fails, or is stopped, updating Status (and invoking notify on
+// every state transition) as it goes. Run may only be called once per
+// Importer.
+func (im *Importer) Run(ctx context.Context, r io.Reader) ([]DomainCount, []RejectedRecord, error) {
+	im.mu.Lock()
+	if im.state != Idle {
+		im.mu.Unlock()
+		return nil, nil, errors.New("importer has already been run")
+	}
+	im.startedAt = time.Now()
+	im.mu.Unlock()
+	im.setState(Importing)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-im.stopCh:
+			cancel()
+		case <-runCtx.Done():
+		}
+	}()
+
+	domainCounts, rejected, err := streamDomainCounts(runCtx, r, im.opts, im.recordProgress)
+
+	if err != nil {
+		im.setState(Failed)
+	} else {
+		im.setState(Finished)
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+	return sortDomains(domainCounts), rejected, nil
+}
+
+// recordProgress updates the running Processed/Rejected counters; it is
+// passed to streamDomainCounts as its onProgress callback.
+func (im *Importer) recordProgress(processed, rejected int) {
+	im.processed.Add(int64(processed))
+	im.rejected.Add(int64(rejected))
+}
+
+// setState transitions state and invokes notify with the resulting Status.
+// notify is called after im.mu is released, so a callback that itself calls
+// Status or Stop (a natural thing for a progress bar or HTTP handler to do)
+// cannot deadlock against the lock held during the transition.
+func (im *Importer) setState(state State) {
+	im.mu.Lock()
+	im.state = state
+	status := im.statusLocked()
+	im.mu.Unlock()
+
+	if im.notify != nil {
+		im.notify(status)
+	}
+}
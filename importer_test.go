@@ -0,0 +1,137 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImporterRunReportsStatus(t *testing.T) {
+	csvData := "first_name,last_name,email,gender,ip_address\n" +
+		"John,Doe,thatGuyDoe@faceSmile.net,Male,53.191.87.821\n" +
+		"Mildred,Hernandez,mhernandez0@github.io,Female,38.194.51.128\n" +
+		"Bad,Row,not-an-email,Male,1.2.3.4\n"
+
+	var transitions []State
+	im := NewImporter(Options{}, func(s Status) {
+		transitions = append(transitions, s.State)
+	})
+
+	if got := im.Status().State; got != Idle {
+		t.Fatalf("Status().State before Run = %v, expected Idle", got)
+	}
+
+	result, _, err := im.Run(context.Background(), strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("Run returned an error: %v", err)
+	}
+
+	if len(result) != 2 {
+		t.Errorf("Run returned %d domains, expected 2", len(result))
+	}
+
+	final := im.Status()
+	if final.State != Finished {
+		t.Errorf("Status().State after Run = %v, expected Finished", final.State)
+	}
+	if final.Processed != 2 {
+		t.Errorf("Status().Processed = %d, expected 2", final.Processed)
+	}
+	if final.Rejected != 1 {
+		t.Errorf("Status().Rejected = %d, expected 1", final.Rejected)
+	}
+	if final.Total != 3 {
+		t.Errorf("Status().Total = %d, expected 3", final.Total)
+	}
+	if final.StartedAt.IsZero() {
+		t.Error("Status().StartedAt is zero after Run")
+	}
+
+	if len(transitions) < 2 || transitions[0] != Importing || transitions[len(transitions)-1] != Finished {
+		t.Errorf("notify transitions = %v, expected to start with Importing and end with Finished", transitions)
+	}
+}
+
+func TestImporterStopCancelsRun(t *testing.T) {
+	im := NewImporter(Options{BatchSize: 1}, nil)
+
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	defer pw.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := im.Run(context.Background(), pr)
+		done <- err
+	}()
+
+	// Give Run a moment to reach Importing before stopping it.
+	for i := 0; i < 100 && im.Status().State != Importing; i++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	// pr is never written to, so Run is parked in a blocking Read when Stop
+	// is called; it must return via ctx cancellation reaching that Read, not
+	// by the pipe unblocking on its own.
+	im.Stop()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("Run returned a nil error after Stop, expected a cancellation error")
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Run error = %v, expected context.Canceled", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after Stop")
+	}
+
+	if got := im.Status().State; got != Failed {
+		t.Errorf("Status().State after a stopped Run = %v, expected Failed", got)
+	}
+}
+
+func TestImporterNotifyCanCallStatusAndStop(t *testing.T) {
+	csvData := "first_name,last_name,email,gender,ip_address\n" +
+		"John,Doe,thatGuyDoe@faceSmile.net,Male,53.191.87.821\n"
+
+	var im *Importer
+	im = NewImporter(Options{}, func(s Status) {
+		// A notify callback calling back into the Importer is a natural
+		// thing for a progress bar or HTTP handler to do; it must not
+		// deadlock against the lock held during the state transition that
+		// triggered this callback. Stop is harmless here (and a no-op once
+		// Run has already finished); what matters is that neither call
+		// ever blocks.
+		im.Status()
+		im.Stop()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		im.Run(context.Background(), strings.NewReader(csvData))
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return; notify likely deadlocked against im.mu")
+	}
+}
+
+func TestImporterRunTwiceFails(t *testing.T) {
+	im := NewImporter(Options{}, nil)
+
+	if _, _, err := im.Run(context.Background(), strings.NewReader("email\n")); err != nil {
+		t.Fatalf("first Run returned an error: %v", err)
+	}
+
+	if _, _, err := im.Run(context.Background(), strings.NewReader("email\n")); err == nil {
+		t.Error("second Run on the same Importer returned a nil error, expected one")
+	}
+}
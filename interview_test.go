@@ -1,176 +1,156 @@
 package customerimporter
 
 import (
+	"context"
 	"encoding/csv"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
 	"testing"
 )
 
-func TestReadCSV(t *testing.T) {
-    filename := "test_customers.csv"
-    expected := [][]string{
-        {"first_name", "last_name", "email", "gender", "ip_address"},
-        {"John", "Doe", "thatGuyDoe@faceSmile.net", "Male", "53.191.87.821"},
-        {"Mildred", "Hernandez", "mhernandez0@github.io", "Female", "38.194.51.128"},
-        {"Bonnie", "Ortiz", "bortiz1@cyberchimps.com", "Female", "197.54.209.129"},
-        {"Dennis", "Henry", "dhenry2@hubpages.com", "Male", "155.75.186.217"},
-    }
-
-    records, err := readCSV(filename)
-    if err != nil {
-        t.Errorf("readCSV returned an error: %v", err)
-    }
-
-		// Convert records to maps for comparison with ignored order
-		expectedMap := make(map[string]struct{})
-		for _, record := range expected {
-				expectedMap[strings.Join(record, ",")] = struct{}{}
-		}
+func TestStreamDomainCounts(t *testing.T) {
+	file, err := os.Open("test_customers.csv")
+	if err != nil {
+		t.Fatalf("failed to open test fixture: %v", err)
+	}
+	defer file.Close()
 
-		recordsMap := make(map[string]struct{})
-		for _, record := range records {
-				recordsMap[strings.Join(record, ",")] = struct{}{}
-		}
+	// Deliberately small WorkerCount/BatchSize so the test exercises the
+	// bounded pool and multi-batch path rather than a single batch.
+	opts := Options{WorkerCount: 2, ChannelBuffer: 1, BatchSize: 1}
 
-		if !reflect.DeepEqual(expectedMap, recordsMap) {
-				t.Errorf("readCSV did not return the expected records")
-		}
-}
-
-func TestCountEmailDomains(t *testing.T) {
-	input := [][]string{
-			{"first_name", "last_name", "email", "gender", "ip_address"},
-			{"John", "Doe", "thatGuyDoe@faceSmile.net", "Male", "53.191.87.821"},
-			{"Mildred", "Hernandez", "mhernandez0@github.io", "Female", "38.194.51.128"},
-			{"Another", "GitUser", "someuser@github.io", "Male", "45.22.321.128"},
-			{"Bonnie", "Ortiz", "bortiz1@cyberchimps.com", "Female", "197.54.209.129"},
-	}
-	expected := map[string]int{
-			"github.io": 2,
-			"cyberchimps.com": 1,
-			"faceSmile.net": 1,
+	result, _, err := streamDomainCounts(context.Background(), file, opts, nil)
+	if err != nil {
+		t.Fatalf("streamDomainCounts returned an error: %v", err)
 	}
 
-	result, err := countEmailDomains(input)
-	if err != nil {
-			t.Fatalf("countEmailDomains returned an error: %v", err)
+	expected := map[string]int{
+		"facesmile.net":   1,
+		"github.io":       1,
+		"cyberchimps.com": 1,
+		"hubpages.com":    1,
 	}
 
 	if !reflect.DeepEqual(result, expected) {
-			t.Errorf("countEmailDomains returned %v, expected %v", result, expected)
+		t.Errorf("streamDomainCounts returned %v, expected %v", result, expected)
 	}
 }
 
-func TestSortDomains(t *testing.T) {
-	domainCounts := map[string]int{
-			"github.io": 5,
-			"cyberchimps.com": 2,
-			"faceSmile.net": 7,
+func TestStreamDomainCountsDefaults(t *testing.T) {
+	file, err := os.Open("test_customers.csv")
+	if err != nil {
+		t.Fatalf("failed to open test fixture: %v", err)
 	}
-	expected := []DomainCount{
-			{Domain: "cyberchimps.com", Count: 2},
-			{Domain: "faceSmile.net", Count: 7},
-			{Domain: "github.io", Count: 5},
+	defer file.Close()
+
+	// Options{} must fall back to usable defaults.
+	result, _, err := streamDomainCounts(context.Background(), file, Options{}, nil)
+	if err != nil {
+		t.Fatalf("streamDomainCounts returned an error: %v", err)
 	}
 
-	result := sortDomains(domainCounts)
-	if !reflect.DeepEqual(result, expected) {
-			t.Errorf("sortDomains returned %v, expected %v", result, expected)
+	if len(result) != 4 {
+		t.Errorf("streamDomainCounts returned %d domains, expected 4", len(result))
 	}
 }
 
-func TestSaveToFile(t *testing.T) {
-	sortedDomains := []DomainCount{
-			{Domain: "faceSmile.net", Count: 7},
-			{Domain: "github.io", Count: 5},
-			{Domain: "cyberchimps.com", Count: 2},
-	}
-	filename := "test_save_to_file.csv"
+func TestProcessReader(t *testing.T) {
+	csvData := "first_name,last_name,email,gender,ip_address\n" +
+		"John,Doe,thatGuyDoe@faceSmile.net,Male,53.191.87.821\n" +
+		"Mildred,Hernandez,mhernandez0@github.io,Female,38.194.51.128\n"
 
-	if err := saveToFile(sortedDomains, filename); err != nil {
-			t.Fatalf("saveToFile returned an error: %v", err)
+	result, _, err := ProcessReader(context.Background(), strings.NewReader(csvData), Options{})
+	if err != nil {
+		t.Fatalf("ProcessReader returned an error: %v", err)
 	}
 
-	// Verify the file content
-	file, err := os.Open(filename)
-	if err != nil {
-			t.Fatalf("Failed to open the file: %v", err)
+	expected := []DomainCount{
+		{Domain: "facesmile.net", Count: 1},
+		{Domain: "github.io", Count: 1},
 	}
-	defer file.Close()
 
-	reader := csv.NewReader(file)
-	lines, err := reader.ReadAll()
-	if err != nil {
-			t.Fatalf("Failed to read from the file: %v", err)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("ProcessReader returned %v, expected %v", result, expected)
 	}
+}
 
-	expectedLines := []string{
-			"faceSmile.net,7",
-			"github.io,5",
-			"cyberchimps.com,2",
+func TestSortDomains(t *testing.T) {
+	domainCounts := map[string]int{
+		"github.io":       5,
+		"cyberchimps.com": 2,
+		"faceSmile.net":   7,
+	}
+	expected := []DomainCount{
+		{Domain: "cyberchimps.com", Count: 2},
+		{Domain: "faceSmile.net", Count: 7},
+		{Domain: "github.io", Count: 5},
 	}
 
-	for i, line := range lines {
-			joinedLine := strings.Join(line, ",")
-			if joinedLine != expectedLines[i] {
-					t.Errorf("Line %d of file is incorrect, got: %s, want: %s", i, joinedLine, expectedLines[i])
-			}
+	result := sortDomains(domainCounts)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("sortDomains returned %v, expected %v", result, expected)
 	}
 }
 
 func TestProcessCustomers(t *testing.T) {
 	inputCSV := "test_customers.csv"
-	outputCSV := "test_output.csv"
+	outputCSV := filepath.Join(t.TempDir(), "test_output.csv")
 
 	// Expected result based on the provided input
 	expectedDomainCounts := []DomainCount{
-			{Domain: "cyberchimps.com", Count: 1},
-			{Domain: "faceSmile.net", Count: 1},
-			{Domain: "github.io", Count: 1},
-			{Domain: "hubpages.com", Count: 1},
+		{Domain: "cyberchimps.com", Count: 1},
+		{Domain: "facesmile.net", Count: 1},
+		{Domain: "github.io", Count: 1},
+		{Domain: "hubpages.com", Count: 1},
 	}
 
-	// Test without saving to file
-	result, err := ProcessCustomers(inputCSV)
+	// Test without a sink
+	result, _, err := ProcessCustomers(context.Background(), inputCSV, Options{})
 	if err != nil {
-			t.Fatalf("ProcessCustomers without file saving returned an error: %v", err)
+		t.Fatalf("ProcessCustomers without a sink returned an error: %v", err)
 	}
 	if !reflect.DeepEqual(result, expectedDomainCounts) {
-			t.Errorf("ProcessCustomers without file saving returned %v, expected %v", result, expectedDomainCounts)
+		t.Errorf("ProcessCustomers without a sink returned %v, expected %v", result, expectedDomainCounts)
 	}
 
-	// Test with saving to file
-	_, err = ProcessCustomers(inputCSV, outputCSV)
+	// Test with a CSVSink writing to a file
+	out, err := os.Create(outputCSV)
 	if err != nil {
-			t.Fatalf("ProcessCustomers with file saving returned an error: %v", err)
+		t.Fatalf("failed to create output file: %v", err)
+	}
+	defer out.Close()
+
+	_, _, err = ProcessCustomers(context.Background(), inputCSV, Options{}, NewCSVSink(out))
+	if err != nil {
+		t.Fatalf("ProcessCustomers with a sink returned an error: %v", err)
 	}
 
 	// Verify the file content
 	file, err := os.Open(outputCSV)
 	if err != nil {
-			t.Fatalf("Failed to open the output file: %v", err)
+		t.Fatalf("Failed to open the output file: %v", err)
 	}
 	defer file.Close()
 
 	reader := csv.NewReader(file)
 	lines, err := reader.ReadAll()
 	if err != nil {
-			t.Fatalf("Failed to read from the output file: %v", err)
+		t.Fatalf("Failed to read from the output file: %v", err)
 	}
 
 	// Validate each line in the output file
 	for i, line := range lines {
-			expectedLine := expectedDomainCounts[i].Domain + "," + strconv.Itoa(expectedDomainCounts[i].Count)
-			joinedLine := strings.Join(line, ",")
-			if joinedLine != expectedLine {
-					t.Errorf("Line %d of output file is incorrect, got: %s, want: %s", i, joinedLine, expectedLine)
-			}
+		expectedLine := expectedDomainCounts[i].Domain + "," + strconv.Itoa(expectedDomainCounts[i].Count)
+		joinedLine := strings.Join(line, ",")
+		if joinedLine != expectedLine {
+			t.Errorf("Line %d of output file is incorrect, got: %s, want: %s", i, joinedLine, expectedLine)
+		}
 	}
 
 	if len(lines) > len(expectedDomainCounts) {
-			t.Errorf("Output file has more lines (%d) than expected (%d)", len(lines), len(expectedDomainCounts))
+		t.Errorf("Output file has more lines (%d) than expected (%d)", len(lines), len(expectedDomainCounts))
 	}
 }
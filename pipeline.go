@@ -0,0 +1,245 @@
+package customerimporter
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// Options configures the streaming import pipeline: how many workers parse
+// records concurrently, how deep the producer/worker channel is buffered,
+// how many records the producer groups into a single batch before handing it
+// to a worker, and how the input itself is decoded. Zero values fall back to
+// sensible defaults sized off the host's CPU count, so the zero value
+// Options{} is always usable.
+type Options struct {
+	WorkerCount   int
+	ChannelBuffer int
+	BatchSize     int
+
+	// Encoding names the input's character encoding: "" or "utf-8" (the
+	// default), "gbk", "latin1"/"iso-8859-1", or "windows-1252". A leading
+	// UTF-8 BOM is stripped regardless of Encoding. See encoding.go.
+	Encoding string
+
+	// Comma, Comment, and LazyQuotes are passed straight through to the
+	// underlying encoding/csv.Reader, so CSVs using tab/semicolon
+	// separators, a comment character, or unescaped quotes can be read.
+	// Comma and Comment default to csv.Reader's own zero-value behavior
+	// (','  and no comment handling) when left unset.
+	Comma      rune
+	Comment    rune
+	LazyQuotes bool
+
+	// StrictEmail controls what happens to a record whose email address
+	// fails validation (see validation.go). When true, the record is
+	// returned in the []RejectedRecord slice alongside its line number and
+	// the reason it was rejected. When false (the default), it is silently
+	// skipped, as in the original implementation.
+	StrictEmail bool
+}
+
+// withDefaults fills in zero fields with defaults tuned for large (1M+ line)
+// imports on a small machine: one worker per CPU, a modest channel buffer so
+// the producer can run ahead of slow workers without buffering the whole
+// file, and a batch size that amortizes channel overhead across many rows.
+func (o Options) withDefaults() Options {
+	if o.WorkerCount <= 0 {
+		o.WorkerCount = runtime.NumCPU()
+	}
+	if o.ChannelBuffer <= 0 {
+		o.ChannelBuffer = o.WorkerCount * 2
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 500
+	}
+	return o
+}
+
+// streamDomainCounts reads CSV records from r with a single producer and
+// folds them into domain counts using a bounded pool of workers, rather than
+// loading every record into memory up front. The producer reads records with
+// encoding/csv.Reader.Read() and groups them into batches of opts.BatchSize,
+// sending each batch over a buffered channel; each worker accumulates its own
+// shard map to avoid lock contention, and the shards are merged once every
+// worker has finished. Records whose email fails validation are dropped, or
+// collected into the returned []RejectedRecord when opts.StrictEmail is set.
+//
+// ctx governs cancellation: once it is done, the producer stops reading and
+// streamDomainCounts returns ctx.Err() (possibly wrapped) after the in-flight batches
+// drain. onProgress, if non-nil, is called after every batch a worker
+// finishes with the number of records it processed and rejected in that
+// batch, so a caller can track progress on a multi-minute import without
+// waiting for it to finish; pass nil to skip progress reporting.
+func streamDomainCounts(ctx context.Context, r io.Reader, opts Options, onProgress func(processed, rejected int)) (map[string]int, []RejectedRecord, error) {
+	opts = opts.withDefaults()
+
+	decoded, err := decodeReader(&ctxReader{ctx: ctx, r: r}, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode input: %w", err)
+	}
+
+	reader := csv.NewReader(decoded)
+	configureCSVReader(reader, opts)
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return map[string]int{}, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	emailColumn, err := findEmailColumn(header)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error finding email column: %w", err)
+	}
+
+	batches := make(chan []lineRecord, opts.ChannelBuffer)
+	results := make(chan workerResult, opts.WorkerCount)
+
+	var workers sync.WaitGroup
+	workers.Add(opts.WorkerCount)
+	for i := 0; i < opts.WorkerCount; i++ {
+		go func() {
+			defer workers.Done()
+			results <- countBatches(batches, emailColumn, opts.StrictEmail, onProgress)
+		}()
+	}
+
+	produceErr := produceBatches(ctx, reader, opts.BatchSize, batches)
+
+	workers.Wait()
+	close(results)
+
+	domainCounts := make(map[string]int)
+	var rejected []RejectedRecord
+	for result := range results {
+		for domain, count := range result.counts {
+			domainCounts[domain] += count
+		}
+		rejected = append(rejected, result.rejected...)
+	}
+
+	if produceErr != nil {
+		return nil, nil, produceErr
+	}
+
+	return domainCounts, rejected, nil
+}
+
+// configureCSVReader applies the opts fields that map directly onto
+// encoding/csv.Reader, leaving Comma/Comment at the csv package's own
+// defaults when unset.
+func configureCSVReader(reader *csv.Reader, opts Options) {
+	if opts.Comma != 0 {
+		reader.Comma = opts.Comma
+	}
+	if opts.Comment != 0 {
+		reader.Comment = opts.Comment
+	}
+	reader.LazyQuotes = opts.LazyQuotes
+}
+
+// lineRecord pairs a CSV record with its 1-indexed line number, so a
+// rejected record can be reported back to the caller.
+type lineRecord struct {
+	line   int
+	fields []string
+}
+
+// workerResult is what a single worker hands back once its batch channel
+// closes: the domains it counted, plus any records it rejected.
+type workerResult struct {
+	counts   map[string]int
+	rejected []RejectedRecord
+}
+
+// produceBatches is the pipeline's single producer: it reads records one at
+// a time from reader, groups them into batches of batchSize, and sends each
+// full batch to the workers over batches. It always closes batches, even on
+// error, so waiting workers unblock. Each record is tagged with the input
+// line it starts on, as reported by reader.FieldPos. It stops early,
+// returning ctx.Err(), once ctx is done.
+func produceBatches(ctx context.Context, reader *csv.Reader, batchSize int, batches chan<- []lineRecord) error {
+	defer close(batches)
+
+	batch := make([]lineRecord, 0, batchSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("reader error: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		batch = append(batch, lineRecord{line: line, fields: record})
+		if len(batch) >= batchSize {
+			select {
+			case batches <- batch:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			batch = make([]lineRecord, 0, batchSize)
+		}
+	}
+
+	if len(batch) > 0 {
+		select {
+		case batches <- batch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// countBatches is a single worker: it drains batches until the channel is
+// closed, tallying email domains into a shard map local to this goroutine so
+// workers never contend on a shared lock. Records with an invalid email are
+// dropped, or recorded as a RejectedRecord when strictEmail is set. onProgress,
+// if non-nil, is called once per batch with that batch's processed/rejected
+// counts.
+func countBatches(batches <-chan []lineRecord, emailColumn int, strictEmail bool, onProgress func(processed, rejected int)) workerResult {
+	result := workerResult{counts: make(map[string]int)}
+	for batch := range batches {
+		var processed, batchRejected int
+		for _, rec := range batch {
+			if len(rec.fields) <= emailColumn {
+				continue
+			}
+
+			domain, reason, ok := extractDomain(rec.fields[emailColumn])
+			if !ok {
+				batchRejected++
+				if strictEmail {
+					result.rejected = append(result.rejected, RejectedRecord{
+						Line:   rec.line,
+						Record: rec.fields,
+						Reason: reason,
+					})
+				}
+				continue
+			}
+
+			result.counts[domain]++
+			processed++
+		}
+
+		if onProgress != nil {
+			onProgress(processed, batchRejected)
+		}
+	}
+	return result
+}
@@ -0,0 +1,178 @@
+package customerimporter
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Sink is the destination for a processed import's domain counts. Write is
+// called once per DomainCount in sorted order; Close is called exactly once
+// after the last Write to flush buffers or commit a final transaction.
+type Sink interface {
+	Write(DomainCount) error
+	Close() error
+}
+
+// CSVSink writes domain counts as CSV rows, preserving the "domain,count"
+// layout the package has always produced. The separator defaults to ',' like
+// encoding/csv.Writer; call SetComma before the first Write to use a
+// different one.
+type CSVSink struct {
+	writer *csv.Writer
+}
+
+// NewCSVSink wraps w in a CSVSink. w is not closed by CSVSink.Close; callers
+// that opened a file themselves are responsible for closing it.
+func NewCSVSink(w io.Writer) *CSVSink {
+	return &CSVSink{writer: csv.NewWriter(w)}
+}
+
+// SetComma overrides the CSV field separator, mirroring csv.Writer.Comma.
+func (s *CSVSink) SetComma(comma rune) {
+	s.writer.Comma = comma
+}
+
+func (s *CSVSink) Write(dc DomainCount) error {
+	return s.writer.Write([]string{dc.Domain, strconv.Itoa(dc.Count)})
+}
+
+// Close flushes any buffered rows and surfaces the first write error the
+// underlying csv.Writer encountered, if any.
+func (s *CSVSink) Close() error {
+	s.writer.Flush()
+	return s.writer.Error()
+}
+
+// JSONSink writes one JSON object per domain count (newline-delimited),
+// convenient for piping import results into log pipelines or analytics
+// tools that expect NDJSON.
+type JSONSink struct {
+	enc *json.Encoder
+}
+
+// NewJSONSink wraps w in a JSONSink. w is not closed by JSONSink.Close.
+func NewJSONSink(w io.Writer) *JSONSink {
+	return &JSONSink{enc: json.NewEncoder(w)}
+}
+
+func (s *JSONSink) Write(dc DomainCount) error {
+	return s.enc.Encode(dc)
+}
+
+// Close is a no-op: JSONSink has nothing to flush.
+func (s *JSONSink) Close() error {
+	return nil
+}
+
+// SQLSink batches INSERTs into a caller-supplied *sql.DB, committing every
+// batchSize rows rather than one transaction per row. This follows the
+// listmonk subimporter's batched-commit pattern for bulk-loading import
+// results into a database without overwhelming it with single-row
+// transactions.
+type SQLSink struct {
+	db        *sql.DB
+	query     string
+	batchSize int
+
+	tx      *sql.Tx
+	stmt    *sql.Stmt
+	pending int
+}
+
+// NewSQLSink returns a SQLSink that executes query (expected to take two
+// args: domain, count) against db, committing every batchSize rows. A
+// batchSize <= 0 defaults to 10,000.
+func NewSQLSink(db *sql.DB, query string, batchSize int) *SQLSink {
+	if batchSize <= 0 {
+		batchSize = 10000
+	}
+	return &SQLSink{db: db, query: query, batchSize: batchSize}
+}
+
+func (s *SQLSink) Write(dc DomainCount) error {
+	if s.tx == nil {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		stmt, err := tx.Prepare(s.query)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to prepare statement: %w", err)
+		}
+		s.tx = tx
+		s.stmt = stmt
+	}
+
+	if _, err := s.stmt.Exec(dc.Domain, dc.Count); err != nil {
+		s.abort()
+		return fmt.Errorf("failed to insert domain count: %w", err)
+	}
+	s.pending++
+
+	if s.pending >= s.batchSize {
+		return s.commit()
+	}
+	return nil
+}
+
+// Close commits any rows left in the current transaction.
+func (s *SQLSink) Close() error {
+	return s.commit()
+}
+
+// commit closes the prepared statement and commits the in-flight
+// transaction, if one is open. It is a no-op when nothing has been written
+// since the last commit.
+func (s *SQLSink) commit() error {
+	if s.tx == nil {
+		return nil
+	}
+
+	if err := s.stmt.Close(); err != nil {
+		s.tx.Rollback()
+		return fmt.Errorf("failed to close statement: %w", err)
+	}
+	if err := s.tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.tx = nil
+	s.stmt = nil
+	s.pending = 0
+	return nil
+}
+
+// abort rolls back and discards the in-flight transaction after a failed
+// Exec, so the connection isn't leaked waiting for a commit that will never
+// come; it is a no-op when nothing is open.
+func (s *SQLSink) abort() {
+	if s.tx == nil {
+		return
+	}
+
+	s.stmt.Close()
+	s.tx.Rollback()
+	s.tx = nil
+	s.stmt = nil
+	s.pending = 0
+}
+
+// writeSink streams every domain count in sortedDomains to sink, in order,
+// then closes it. Close is called even when a Write fails partway through,
+// so a CSVSink still flushes the rows written before the failure. A SQLSink
+// rolls back its open transaction itself when an Exec fails, so its Close
+// then has nothing left to commit.
+func writeSink(sink Sink, sortedDomains []DomainCount) error {
+	for _, dc := range sortedDomains {
+		if err := sink.Write(dc); err != nil {
+			sink.Close()
+			return fmt.Errorf("failed to write domain count: %w", err)
+		}
+	}
+	return sink.Close()
+}
@@ -0,0 +1,195 @@
+package customerimporter
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCSVSink(t *testing.T) {
+	domains := []DomainCount{
+		{Domain: "faceSmile.net", Count: 7},
+		{Domain: "github.io", Count: 5},
+		{Domain: "cyberchimps.com", Count: 2},
+	}
+
+	var buf bytes.Buffer
+	sink := NewCSVSink(&buf)
+
+	if err := writeSink(sink, domains); err != nil {
+		t.Fatalf("writeSink returned an error: %v", err)
+	}
+
+	expected := "faceSmile.net,7\ngithub.io,5\ncyberchimps.com,2\n"
+	if buf.String() != expected {
+		t.Errorf("CSVSink wrote %q, expected %q", buf.String(), expected)
+	}
+}
+
+func TestCSVSinkCustomComma(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewCSVSink(&buf)
+	sink.SetComma(';')
+
+	if err := writeSink(sink, []DomainCount{{Domain: "github.io", Count: 5}}); err != nil {
+		t.Fatalf("writeSink returned an error: %v", err)
+	}
+
+	if buf.String() != "github.io;5\n" {
+		t.Errorf("CSVSink wrote %q, expected %q", buf.String(), "github.io;5\n")
+	}
+}
+
+func TestJSONSink(t *testing.T) {
+	domains := []DomainCount{
+		{Domain: "github.io", Count: 5},
+		{Domain: "cyberchimps.com", Count: 2},
+	}
+
+	var buf bytes.Buffer
+	if err := writeSink(NewJSONSink(&buf), domains); err != nil {
+		t.Fatalf("writeSink returned an error: %v", err)
+	}
+
+	dec := json.NewDecoder(strings.NewReader(buf.String()))
+	for _, want := range domains {
+		var got DomainCount
+		if err := dec.Decode(&got); err != nil {
+			t.Fatalf("failed to decode JSON line: %v", err)
+		}
+		if got != want {
+			t.Errorf("JSONSink wrote %+v, expected %+v", got, want)
+		}
+	}
+}
+
+// fakeSQLDriver is a minimal database/sql/driver.Driver that records how
+// many statements were executed and how many transactions were committed,
+// so SQLSink's batching behavior can be verified without a real database.
+type fakeSQLDriver struct {
+	mu       sync.Mutex
+	execs    int
+	commits  int
+	failExec bool
+}
+
+func (d *fakeSQLDriver) Open(name string) (driver.Conn, error) {
+	return &fakeSQLConn{driver: d}, nil
+}
+
+type fakeSQLConn struct {
+	driver *fakeSQLDriver
+}
+
+func (c *fakeSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeSQLStmt{conn: c}, nil
+}
+func (c *fakeSQLConn) Close() error { return nil }
+func (c *fakeSQLConn) Begin() (driver.Tx, error) {
+	return &fakeSQLTx{conn: c}, nil
+}
+
+type fakeSQLStmt struct {
+	conn *fakeSQLConn
+}
+
+func (s *fakeSQLStmt) Close() error  { return nil }
+func (s *fakeSQLStmt) NumInput() int { return -1 }
+func (s *fakeSQLStmt) Exec(args []driver.Value) (driver.Result, error) {
+	d := s.conn.driver
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.failExec {
+		return nil, errors.New("fakeSQLStmt: exec failed")
+	}
+	d.execs++
+	return driver.RowsAffected(1), nil
+}
+func (s *fakeSQLStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("fakeSQLStmt: query not supported")
+}
+
+type fakeSQLTx struct {
+	conn *fakeSQLConn
+}
+
+func (t *fakeSQLTx) Commit() error {
+	d := t.conn.driver
+	d.mu.Lock()
+	d.commits++
+	d.mu.Unlock()
+	return nil
+}
+func (t *fakeSQLTx) Rollback() error { return nil }
+
+// fakeSQLDriverSeq gives each newFakeSQLDB call its own driver name, since
+// sql.Register panics if the same name is registered twice - which t.Name()
+// alone doesn't guard against under a test retry/repeat harness (e.g.
+// go test -count=2).
+var fakeSQLDriverSeq atomic.Int64
+
+func newFakeSQLDB(t *testing.T, d *fakeSQLDriver) *sql.DB {
+	t.Helper()
+
+	name := fmt.Sprintf("%s-%d", t.Name(), fakeSQLDriverSeq.Add(1))
+	sql.Register(name, d)
+
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatalf("failed to open fake db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSQLSinkCommitsEveryBatchSize(t *testing.T) {
+	d := &fakeSQLDriver{}
+	db := newFakeSQLDB(t, d)
+
+	sink := NewSQLSink(db, "INSERT INTO domain_counts (domain, count) VALUES (?, ?)", 2)
+
+	domains := []DomainCount{
+		{Domain: "a.com", Count: 1},
+		{Domain: "b.com", Count: 2},
+		{Domain: "c.com", Count: 3},
+	}
+
+	if err := writeSink(sink, domains); err != nil {
+		t.Fatalf("writeSink returned an error: %v", err)
+	}
+
+	d.mu.Lock()
+	execs, commits := d.execs, d.commits
+	d.mu.Unlock()
+
+	if execs != 3 {
+		t.Errorf("driver recorded %d execs, expected 3", execs)
+	}
+	// batchSize=2 over 3 rows: one commit fires mid-stream at row 2, and
+	// Close commits the trailing row.
+	if commits != 2 {
+		t.Errorf("driver recorded %d commits, expected 2 (one at batchSize, one on Close)", commits)
+	}
+}
+
+func TestSQLSinkAbortsOnExecError(t *testing.T) {
+	d := &fakeSQLDriver{failExec: true}
+	db := newFakeSQLDB(t, d)
+
+	sink := NewSQLSink(db, "INSERT INTO domain_counts (domain, count) VALUES (?, ?)", 10)
+
+	if err := sink.Write(DomainCount{Domain: "a.com", Count: 1}); err == nil {
+		t.Fatal("Write returned a nil error, expected the driver's exec failure")
+	}
+
+	if sink.tx != nil || sink.stmt != nil {
+		t.Error("Write left a transaction open after an Exec failure")
+	}
+}
@@ -0,0 +1,45 @@
+package customerimporter
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// RejectedRecord describes a CSV record that was skipped because its email
+// address failed validation. Line is the 1-indexed CSV line (the header is
+// line 1), matching what a user would see if they opened the file in an
+// editor.
+type RejectedRecord struct {
+	Line   int
+	Record []string
+	Reason string
+}
+
+// extractDomain validates email with net/mail.ParseAddress and, on success,
+// returns its normalized domain: lowercased and converted to punycode via
+// IDNA ToASCII so internationalized domains collapse to the same form as
+// their ASCII equivalent. On failure it returns a human-readable reason and
+// ok=false; the caller decides whether that's a silent skip or a
+// RejectedRecord, based on Options.StrictEmail.
+func extractDomain(email string) (domain string, reason string, ok bool) {
+	addr, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil {
+		return "", fmt.Sprintf("invalid email address: %v", err), false
+	}
+
+	at := strings.LastIndex(addr.Address, "@")
+	if at < 0 || at == len(addr.Address)-1 {
+		return "", "address is missing a domain", false
+	}
+
+	lower := strings.ToLower(addr.Address[at+1:])
+	ascii, err := idna.ToASCII(lower)
+	if err != nil {
+		return "", fmt.Sprintf("invalid domain %q: %v", lower, err), false
+	}
+
+	return ascii, "", true
+}
@@ -0,0 +1,87 @@
+package customerimporter
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractDomainNormalizesCase(t *testing.T) {
+	domain, _, ok := extractDomain("thatGuyDoe@faceSmile.net")
+	if !ok {
+		t.Fatal("extractDomain returned ok=false for a valid address")
+	}
+	if domain != "facesmile.net" {
+		t.Errorf("extractDomain returned domain %q, expected %q", domain, "facesmile.net")
+	}
+
+	upper, _, ok := extractDomain("someone@FaceSmile.NET")
+	if !ok {
+		t.Fatal("extractDomain returned ok=false for a valid address")
+	}
+	if upper != domain {
+		t.Errorf("extractDomain did not normalize case: %q vs %q", upper, domain)
+	}
+}
+
+func TestExtractDomainRejectsInvalid(t *testing.T) {
+	if _, reason, ok := extractDomain("not-an-email"); ok || reason == "" {
+		t.Errorf("extractDomain(%q) = ok:%v reason:%q, expected a rejection with a reason", "not-an-email", ok, reason)
+	}
+}
+
+func TestExtractDomainIDNA(t *testing.T) {
+	domain, _, ok := extractDomain("user@xn--nxasmq6b.com")
+	if !ok {
+		t.Fatal("extractDomain returned ok=false for a punycode domain")
+	}
+	if domain != "xn--nxasmq6b.com" {
+		t.Errorf("extractDomain returned %q, expected the domain unchanged", domain)
+	}
+}
+
+func TestStreamDomainCountsStrictEmailRejectsInvalid(t *testing.T) {
+	csvData := "first_name,last_name,email,gender,ip_address\n" +
+		"John,Doe,thatGuyDoe@faceSmile.net,Male,53.191.87.821\n" +
+		"Bad,Row,not-an-email,Male,1.2.3.4\n"
+
+	counts, rejected, err := streamDomainCounts(context.Background(), strings.NewReader(csvData), Options{StrictEmail: true}, nil)
+	if err != nil {
+		t.Fatalf("streamDomainCounts returned an error: %v", err)
+	}
+
+	expectedCounts := map[string]int{"facesmile.net": 1}
+	if !reflect.DeepEqual(counts, expectedCounts) {
+		t.Errorf("streamDomainCounts returned %v, expected %v", counts, expectedCounts)
+	}
+
+	if len(rejected) != 1 {
+		t.Fatalf("streamDomainCounts returned %d rejected records, expected 1", len(rejected))
+	}
+	if rejected[0].Line != 3 {
+		t.Errorf("rejected record line = %d, expected 3", rejected[0].Line)
+	}
+	if rejected[0].Reason == "" {
+		t.Error("rejected record has no reason")
+	}
+}
+
+func TestStreamDomainCountsNonStrictSkipsInvalid(t *testing.T) {
+	csvData := "first_name,last_name,email,gender,ip_address\n" +
+		"John,Doe,thatGuyDoe@faceSmile.net,Male,53.191.87.821\n" +
+		"Bad,Row,not-an-email,Male,1.2.3.4\n"
+
+	counts, rejected, err := streamDomainCounts(context.Background(), strings.NewReader(csvData), Options{}, nil)
+	if err != nil {
+		t.Fatalf("streamDomainCounts returned an error: %v", err)
+	}
+
+	expectedCounts := map[string]int{"facesmile.net": 1}
+	if !reflect.DeepEqual(counts, expectedCounts) {
+		t.Errorf("streamDomainCounts returned %v, expected %v", counts, expectedCounts)
+	}
+	if rejected != nil {
+		t.Errorf("streamDomainCounts returned rejected records %v, expected none", rejected)
+	}
+}
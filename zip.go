@@ -0,0 +1,78 @@
+package customerimporter
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// ProcessZip opens the ZIP archive at path, streams every *.csv entry it
+// contains through the same pipeline as ProcessReader, and aggregates domain
+// counts across all of them. This lets callers import multi-file customer
+// dumps that ship as a single compressed export without extracting to disk
+// first. ctx governs cancellation, same as ProcessReader.
+func ProcessZip(ctx context.Context, path string, opts Options) ([]DomainCount, []RejectedRecord, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open zip archive: %w", err)
+	}
+	defer r.Close()
+
+	return processZipEntries(ctx, &r.Reader, opts)
+}
+
+// ProcessZipReader is the io.ReaderAt-based counterpart to ProcessZip, for
+// callers that already hold the archive open (an *os.File, a
+// bytes.Reader over a downloaded export, ...) rather than a path on disk.
+func ProcessZipReader(ctx context.Context, ra io.ReaderAt, size int64, opts Options) ([]DomainCount, []RejectedRecord, error) {
+	zr, err := zip.NewReader(ra, size)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open zip archive: %w", err)
+	}
+
+	return processZipEntries(ctx, zr, opts)
+}
+
+// processZipEntries streams each *.csv entry in zr through the pipeline and
+// folds the resulting domain counts and rejected records together before
+// sorting once at the end. It stops between entries once ctx is done.
+func processZipEntries(ctx context.Context, zr *zip.Reader, opts Options) ([]DomainCount, []RejectedRecord, error) {
+	domainCounts := make(map[string]int)
+	var rejected []RejectedRecord
+
+	for _, entry := range zr.File {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		if entry.FileInfo().IsDir() || !strings.EqualFold(filepath.Ext(entry.Name), ".csv") {
+			continue
+		}
+
+		counts, entryRejected, err := countZipEntry(ctx, entry, opts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to process %s: %w", entry.Name, err)
+		}
+
+		for domain, count := range counts {
+			domainCounts[domain] += count
+		}
+		rejected = append(rejected, entryRejected...)
+	}
+
+	return sortDomains(domainCounts), rejected, nil
+}
+
+// countZipEntry streams a single archive entry through the pipeline.
+func countZipEntry(ctx context.Context, entry *zip.File, opts Options) (map[string]int, []RejectedRecord, error) {
+	rc, err := entry.Open()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open entry: %w", err)
+	}
+	defer rc.Close()
+
+	return streamDomainCounts(ctx, rc, opts, nil)
+}
@@ -0,0 +1,86 @@
+package customerimporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTestZip builds a ZIP archive containing the given name->CSV-content
+// entries and returns its bytes.
+func writeTestZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("failed to create zip entry %s: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("failed to write zip entry %s: %v", name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to close zip writer: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestProcessZip(t *testing.T) {
+	data := writeTestZip(t, map[string]string{
+		"customers1.csv": "first_name,last_name,email,gender,ip_address\n" +
+			"John,Doe,thatGuyDoe@faceSmile.net,Male,53.191.87.821\n",
+		"customers2.csv": "first_name,last_name,email,gender,ip_address\n" +
+			"Mildred,Hernandez,mhernandez0@github.io,Female,38.194.51.128\n" +
+			"Another,GitUser,someuser@github.io,Male,45.22.321.128\n",
+		"README.txt": "not a csv, should be skipped\n",
+	})
+
+	path := filepath.Join(t.TempDir(), "customers.zip")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write test zip: %v", err)
+	}
+
+	result, _, err := ProcessZip(context.Background(), path, Options{})
+	if err != nil {
+		t.Fatalf("ProcessZip returned an error: %v", err)
+	}
+
+	expected := []DomainCount{
+		{Domain: "facesmile.net", Count: 1},
+		{Domain: "github.io", Count: 2},
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("ProcessZip returned %v, expected %v", result, expected)
+	}
+}
+
+func TestProcessZipReader(t *testing.T) {
+	data := writeTestZip(t, map[string]string{
+		"customers.csv": "first_name,last_name,email,gender,ip_address\n" +
+			"Bonnie,Ortiz,bortiz1@cyberchimps.com,Female,197.54.209.129\n",
+	})
+
+	result, _, err := ProcessZipReader(context.Background(), bytes.NewReader(data), int64(len(data)), Options{})
+	if err != nil {
+		t.Fatalf("ProcessZipReader returned an error: %v", err)
+	}
+
+	expected := []DomainCount{
+		{Domain: "cyberchimps.com", Count: 1},
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("ProcessZipReader returned %v, expected %v", result, expected)
+	}
+}